@@ -1,116 +1,298 @@
 package cache
 
-// a memory capped LRU cache
+// a memory capped LRU cache with per-item TTLs
 import (
+	"container/heap"
 	"container/list"
 	"errors"
+	"runtime"
 	"sync"
 	"time"
+	"unsafe"
 )
 
-type Clock struct {
-	instant time.Time
+// NoExpiration is a sentinel TTL indicating that an item should never expire.
+const NoExpiration time.Duration = -1
+
+// DefaultExpiration is a sentinel TTL indicating that an item should use the
+// default expiration the Cache was constructed with.
+const DefaultExpiration time.Duration = 0
+
+// Bytes is a Cache specialized for the original string-keyed, []byte-valued
+// usage.
+type Bytes = Cache[string, []byte]
+
+// lruCore holds a Cache's data and janitor state. Cache wraps a *lruCore
+// rather than embedding these fields directly so that a runtime.SetFinalizer
+// on the outer Cache can stop lruCore's background goroutines once the
+// Cache becomes unreachable, even if a caller forgets to call StopEviction.
+// The janitor goroutines close over the *lruCore itself, never the outer
+// Cache, so they don't keep the Cache (and therefore the finalizer target)
+// reachable forever.
+type lruCore[K comparable, V any] struct {
+	sync.Mutex
+	data              map[K]*entry[V]
+	lru               *list.List
+	expirations       *expirationQueue[V]
+	ticker            *time.Ticker
+	tickerDone        chan struct{}
+	expirationTicker  *time.Ticker
+	expirationDone    chan struct{}
+	bytesReferenced   uint64
+	clock             *clock
+	defaultExpiration time.Duration
+	onEvicted         func(key K, value V, reason EvictionReason)
+	evictionBuffer    []evictionRecord[K, V]
+	inflight          map[K]*inflightCall[V]
+}
+
+// A Cache is a generic, optionally memory-capped LRU cache with per-item
+// TTLs.
+type Cache[K comparable, V any] struct {
+	*lruCore[K, V]
 }
 
-func (c *Clock) Now() time.Time {
-	if c == nil {
-		return time.Now()
+// New constructs a Cache whose items expire after defaultExpiration unless
+// given a different TTL at Set time. Pass NoExpiration for defaultExpiration
+// to make items live forever unless they specify their own TTL. If
+// cleanupInterval is greater than zero, a background goroutine sweeps
+// expired items on that interval; otherwise expired items are reaped only on
+// Read, by DeleteExpired, or by the janitor that StartEviction starts.
+func New[K comparable, V any](defaultExpiration, cleanupInterval time.Duration) *Cache[K, V] {
+	core := &lruCore[K, V]{
+		data:              map[K]*entry[V]{},
+		lru:               list.New(),
+		expirations:       &expirationQueue[V]{},
+		defaultExpiration: defaultExpiration,
 	}
-	return c.instant
+
+	if cleanupInterval > 0 {
+		core.expirationTicker = time.NewTicker(cleanupInterval)
+		done := make(chan struct{})
+		core.expirationDone = done
+		tickerC := core.expirationTicker.C
+		go func() {
+			for {
+				select {
+				case <-done:
+					return
+				case <-tickerC:
+					core.DeleteExpired()
+				}
+			}
+		}()
+	}
+
+	c := &Cache[K, V]{lruCore: core}
+	runtime.SetFinalizer(c, func(c *Cache[K, V]) {
+		c.lruCore.stopTickers()
+	})
+	return c
 }
 
-// An Entry represents a value in a Cache
-type Entry struct {
-	lock        sync.RWMutex
-	data        []byte
-	expiration  time.Time
-	listElement *list.Element
+// DeleteExpired synchronously removes every currently expired entry from
+// the cache. It walks the expiration heap rather than the whole map, so its
+// cost is proportional to the number of expired entries, not the cache's
+// size.
+func (c *lruCore[K, V]) DeleteExpired() {
+	c.Lock()
+	c.deleteExpiredLocked()
+	c.Unlock()
+	c.flushEvictions()
 }
 
-// Update will set the value and expiration of an entry in a thread-safe manner
-func (e *Entry) Update(value []byte, expiration time.Time) {
-	e.lock.Lock()
-	defer e.lock.Unlock()
+// Not thread safe
+func (c *lruCore[K, V]) deleteExpiredLocked() {
+	now := c.clock.now()
+	for c.expirations.Len() > 0 && (*c.expirations)[0].expiration.Before(now) {
+		e := heap.Pop(c.expirations).(*entry[V])
+		key := e.listElement.Value.(K)
+		c.lru.Remove(e.listElement)
+		delete(c.data, key)
+		c.bytesReferenced -= sizeOf(e.value)
+		c.bufferEviction(key, e.value, ReasonExpired)
+	}
+}
 
-	e.data = value
-	e.expiration = expiration
+// Not thread safe. reason is buffered for delivery to OnEvicted once the
+// caller releases the lock and calls flushEvictions.
+func (c *lruCore[K, V]) removeEntryLocked(key K, e *entry[V], reason EvictionReason) {
+	c.lru.Remove(e.listElement)
+	delete(c.data, key)
+	c.bytesReferenced -= sizeOf(e.value)
+	if e.heapIndex != -1 {
+		heap.Remove(c.expirations, e.heapIndex)
+	}
+	c.bufferEviction(key, e.value, reason)
 }
 
-// Read will return the value of an entry in a thread-safe manner
-func (e *Entry) Read() []byte {
-	e.lock.RLock()
-	defer e.lock.RUnlock()
+// reindexExpiration keeps e's position in the expiration heap consistent
+// with its current noExpire/expiration fields.
+//
+// Not thread safe
+func (c *lruCore[K, V]) reindexExpiration(e *entry[V]) {
+	if e.noExpire {
+		if e.heapIndex != -1 {
+			heap.Remove(c.expirations, e.heapIndex)
+		}
+		return
+	}
+	if e.heapIndex == -1 {
+		heap.Push(c.expirations, e)
+	} else {
+		heap.Fix(c.expirations, e.heapIndex)
+	}
+}
 
-	return e.data
+// expirationFor resolves a TTL passed to Set/Add/Replace into an absolute
+// expiration time, falling back to the cache's default expiration when ttl
+// is DefaultExpiration. A ttl that resolves to zero or negative (including
+// NoExpiration) means the item never expires.
+func (c *lruCore[K, V]) expirationFor(ttl time.Duration) (expiration time.Time, noExpire bool) {
+	if ttl == DefaultExpiration {
+		ttl = c.defaultExpiration
+	}
+	if ttl > 0 {
+		return c.clock.now().Add(ttl), false
+	}
+	return time.Time{}, true
 }
 
-// A Cache is an optionally memory-capped LRU cache
-type Cache struct {
-	sync.Mutex
-	data            map[string]*Entry
-	lru             *list.List
-	ticker          *time.Ticker
-	bytesReferenced uint64
-	clock           *Clock
+// Set performs a thread-safe upsert, replacing any existing value for key
+// and resetting its expiration to ttl from now. Pass NoExpiration for an
+// item that should never expire, or DefaultExpiration to use the Cache's
+// configured default TTL.
+func (c *lruCore[K, V]) Set(key K, value V, ttl time.Duration) {
+	expiration, noExpire := c.expirationFor(ttl)
+
+	c.Lock()
+	c.setLocked(key, value, expiration, noExpire)
+	c.Unlock()
+	c.flushEvictions()
 }
 
-func (c *Cache) Read(key string) []byte {
+// SetDefault is equivalent to Set(key, value, DefaultExpiration).
+func (c *lruCore[K, V]) SetDefault(key K, value V) {
+	c.Set(key, value, DefaultExpiration)
+}
+
+// Add sets key to value only if key is not already present with an
+// unexpired entry, returning an error otherwise.
+func (c *lruCore[K, V]) Add(key K, value V, ttl time.Duration) error {
+	expiration, noExpire := c.expirationFor(ttl)
+
 	c.Lock()
 
-	e, ok := c.data[key]
-	if !ok {
+	if e, exists := c.data[key]; exists && !e.expired(c.clock.now()) {
 		c.Unlock()
-		return nil
+		return errors.New("cache: key already exists")
 	}
 
-	if e.expiration.Before(c.clock.Now()) {
-		c.lru.Remove(e.listElement)
-		delete(c.data, key)
+	c.setLocked(key, value, expiration, noExpire)
+	c.Unlock()
+	c.flushEvictions()
+	return nil
+}
+
+// Replace sets key to value only if key is already present with an
+// unexpired entry, returning an error otherwise.
+func (c *lruCore[K, V]) Replace(key K, value V, ttl time.Duration) error {
+	expiration, noExpire := c.expirationFor(ttl)
+
+	c.Lock()
+
+	e, exists := c.data[key]
+	if !exists || e.expired(c.clock.now()) {
 		c.Unlock()
-		return nil
+		return errors.New("cache: key does not exist")
 	}
 
-	c.lru.MoveToFront(e.listElement)
+	c.setLocked(key, value, expiration, noExpire)
 	c.Unlock()
-
-	return e.Read()
+	c.flushEvictions()
+	return nil
 }
 
-// Set performs a thread-safe upsert operation on a cache
-func (c *Cache) Set(key string, value []byte, expiration time.Time) {
+// Delete removes key from the cache, invoking OnEvicted with
+// ReasonManualDelete if it was present.
+func (c *lruCore[K, V]) Delete(key K) {
 	c.Lock()
-	entry, exists := c.data[key]
-	if exists {
-		c.markEntryTouched(entry)
+
+	e, ok := c.data[key]
+	if !ok {
 		c.Unlock()
-		// The item may have been removed from the cache between releasing the lock
-		// and performing the write below, this would only happen if entire cache
-		// flushed through during update
-		entry.Update(value, expiration)
-	} else {
-		lruElement := c.lru.PushFront(key)
+		return
+	}
+
+	c.removeEntryLocked(key, e, ReasonManualDelete)
+	c.Unlock()
+	c.flushEvictions()
+}
 
-		c.data[key] = &Entry{
-			data:        value,
+// Not thread safe
+func (c *lruCore[K, V]) setLocked(key K, value V, expiration time.Time, noExpire bool) {
+	if e, exists := c.data[key]; exists {
+		c.bytesReferenced -= sizeOf(e.value)
+		c.bufferEviction(key, e.value, ReasonReplaced)
+		e.value = value
+		e.expiration = expiration
+		e.noExpire = noExpire
+		c.lru.MoveToFront(e.listElement)
+		c.reindexExpiration(e)
+	} else {
+		listElement := c.lru.PushFront(key)
+		e := &entry[V]{
+			value:       value,
 			expiration:  expiration,
-			listElement: lruElement,
+			noExpire:    noExpire,
+			listElement: listElement,
+			heapIndex:   -1,
 		}
+		c.data[key] = e
+		c.reindexExpiration(e)
+	}
+	c.bytesReferenced += sizeOf(value)
+}
 
-		c.bytesReferenced += uint64(len(value))
+// Read returns the value stored for key and true, or the zero value of V
+// and false if key is absent or has expired.
+func (c *lruCore[K, V]) Read(key K) (V, bool) {
+	c.Lock()
 
+	e, ok := c.data[key]
+	if !ok {
 		c.Unlock()
+		var zero V
+		return zero, false
 	}
-}
 
-// Not thread safe
-func (c *Cache) markEntryTouched(e *Entry) {
-	if e.listElement != nil {
-		c.lru.MoveToFront(e.listElement)
+	if e.expired(c.clock.now()) {
+		c.removeEntryLocked(key, e, ReasonExpired)
+		c.Unlock()
+		c.flushEvictions()
+		var zero V
+		return zero, false
 	}
+
+	c.lru.MoveToFront(e.listElement)
+	value := e.value
+	c.Unlock()
+
+	return value, true
 }
 
-// StartEviction starts the eviction process or returns an error if one exists
-func (c *Cache) StartEviction(memoryLimit uint64, checkInterval time.Duration) error {
+// BytesReferenced returns the cache's current memory accounting total, as
+// tracked by Set/Replace/Add and decremented on eviction.
+func (c *lruCore[K, V]) BytesReferenced() uint64 {
+	c.Lock()
+	defer c.Unlock()
+	return c.bytesReferenced
+}
+
+// StartEviction starts the eviction process or returns an error if one
+// already exists. Each tick first runs a DeleteExpired pass, then evicts
+// from the LRU tail while the cache is over memoryLimit.
+func (c *lruCore[K, V]) StartEviction(memoryLimit uint64, checkInterval time.Duration) error {
 	c.Lock()
 	defer c.Unlock()
 
@@ -119,16 +301,25 @@ func (c *Cache) StartEviction(memoryLimit uint64, checkInterval time.Duration) e
 	}
 
 	c.ticker = time.NewTicker(checkInterval)
+	done := make(chan struct{})
+	c.tickerDone = done
+	tickerC := c.ticker.C
 	go func() {
-		for _ = range c.ticker.C {
-			c.Lock()
-			for c.bytesReferenced > memoryLimit && c.lru.Len() > 0 {
-				entryKey := c.lru.Remove(c.lru.Back()).(string)
-				entry := c.data[entryKey]
-				c.bytesReferenced -= uint64(len(entry.data))
-				delete(c.data, entryKey)
+		for {
+			select {
+			case <-done:
+				return
+			case <-tickerC:
+				c.Lock()
+				c.deleteExpiredLocked()
+				for c.bytesReferenced > memoryLimit && c.lru.Len() > 0 {
+					key := c.lru.Back().Value.(K)
+					e := c.data[key]
+					c.removeEntryLocked(key, e, ReasonMemoryPressure)
+				}
+				c.Unlock()
+				c.flushEvictions()
 			}
-			c.Unlock()
 		}
 	}()
 
@@ -136,22 +327,54 @@ func (c *Cache) StartEviction(memoryLimit uint64, checkInterval time.Duration) e
 }
 
 // StopEviction will halt any background eviction process if it exists
-func (c *Cache) StopEviction() {
+func (c *lruCore[K, V]) StopEviction() {
 	c.Lock()
 	defer c.Unlock()
+	c.stopEvictionLocked()
+}
 
+// Not thread safe
+func (c *lruCore[K, V]) stopEvictionLocked() {
 	if c.ticker != nil {
 		c.ticker.Stop()
 		c.ticker = nil
 	}
+	if c.tickerDone != nil {
+		close(c.tickerDone)
+		c.tickerDone = nil
+	}
+}
+
+// stopTickers halts both the memory-cap janitor started by StartEviction
+// and the expiration janitor started by New, if running. It is invoked by
+// the finalizer Cache registers on itself, as a last resort against a
+// caller that drops a Cache without calling StopEviction.
+func (c *lruCore[K, V]) stopTickers() {
+	c.Lock()
+	defer c.Unlock()
+
+	c.stopEvictionLocked()
+
+	if c.expirationTicker != nil {
+		c.expirationTicker.Stop()
+		c.expirationTicker = nil
+	}
+	if c.expirationDone != nil {
+		close(c.expirationDone)
+		c.expirationDone = nil
+	}
 }
 
-// NewCache constructs an optionally memory-capped LRU cache
-func NewCache() *Cache {
-	cache := &Cache{
-		data:            map[string]*Entry{},
-		lru:             list.New(),
-		bytesReferenced: 0,
+// sizeOf estimates the memory footprint of a value for the memoryLimit
+// accounting used by StartEviction. []byte and string values are measured
+// by their length; other types fall back to their static in-memory size.
+func sizeOf[V any](v V) uint64 {
+	switch val := any(v).(type) {
+	case []byte:
+		return uint64(len(val))
+	case string:
+		return uint64(len(val))
+	default:
+		return uint64(unsafe.Sizeof(v))
 	}
-	return cache
 }
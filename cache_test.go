@@ -2,112 +2,246 @@ package cache
 
 import (
 	"reflect"
+	"runtime"
 	"strconv"
 	"testing"
 	"time"
 )
 
-func TestSetGetUpdate(t *testing.T) {
-	cache := NewCache()
+func TestSetReadUpdate(t *testing.T) {
+	c := New[string, []byte](NoExpiration, 0)
 
 	testKey := "test"
 	testVal := []byte("This is a test!")
-	expiration := time.Now().Add(time.Hour)
 
-	cache.Set(testKey, testVal, expiration)
+	c.Set(testKey, testVal, time.Hour)
 
-	readVal := cache.Get(testKey)
-	if !reflect.DeepEqual(readVal, testVal) {
+	readVal, ok := c.Read(testKey)
+	if !ok || !reflect.DeepEqual(readVal, testVal) {
 		t.Error("Key put in store not equal to key read from store")
 	}
 
 	testVal = []byte("Different value")
-	cache.Set(testKey, testVal, expiration)
+	c.Set(testKey, testVal, time.Hour)
 
-	readVal = cache.Get(testKey)
-	if !reflect.DeepEqual(readVal, testVal) {
+	readVal, ok = c.Read(testKey)
+	if !ok || !reflect.DeepEqual(readVal, testVal) {
 		t.Error("Key put in store not equal to key read from store")
 	}
 }
 
-func TestNonexistentGet(t *testing.T) {
-	cache := NewCache()
+func TestNonexistentRead(t *testing.T) {
+	c := New[string, []byte](NoExpiration, 0)
+
+	_, ok := c.Read("lol")
+	if ok {
+		t.Error("Non-existent values should not be found")
+	}
+}
+
+func TestNoExpiration(t *testing.T) {
+	c := New[string, []byte](NoExpiration, 0)
+
+	c.Set("test", []byte("forever"), NoExpiration)
+	c.clock = &clock{instant: time.Now().Add(100 * 365 * 24 * time.Hour)}
+
+	_, ok := c.Read("test")
+	if !ok {
+		t.Error("Expected a NoExpiration item to survive an arbitrarily distant read")
+	}
+}
+
+func TestDefaultExpiration(t *testing.T) {
+	c := New[string, []byte](time.Hour, 0)
+
+	c.SetDefault("test", []byte("default ttl"))
+
+	c.clock = &clock{instant: time.Now().Add(2 * time.Hour)}
+	if _, ok := c.Read("test"); ok {
+		t.Error("Expected the item to have expired using the cache's default TTL")
+	}
+}
+
+func TestJanitorStopsViaFinalizer(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	func() {
+		c := New[string, []byte](time.Hour, time.Millisecond)
+		c.StartEviction(1<<30, time.Millisecond)
+		c.Set("test", []byte("v"), NoExpiration)
+	}()
+
+	// This is pretty jank, but there's no non-time-based way to observe a
+	// GC-triggered goroutine exit: give the finalizer a chance to run and
+	// the janitor goroutines a chance to notice their stopped tickers.
+	for i := 0; i < 20; i++ {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+		if runtime.NumGoroutine() <= before {
+			return
+		}
+	}
+
+	t.Errorf("Expected the janitor goroutines to exit once the Cache became unreachable, goroutines before=%d after=%d", before, runtime.NumGoroutine())
+}
+
+func TestDeleteExpired(t *testing.T) {
+	c := New[string, []byte](NoExpiration, 0)
+
+	c.Set("expired", []byte("a"), time.Hour)
+	c.Set("forever", []byte("b"), NoExpiration)
+	c.clock = &clock{instant: time.Now().Add(2 * time.Hour)}
+
+	c.DeleteExpired()
+
+	if _, ok := c.Read("expired"); ok {
+		t.Error("Expected DeleteExpired to remove the expired entry")
+	}
+	if _, ok := c.Read("forever"); !ok {
+		t.Error("Expected DeleteExpired to leave the NoExpiration entry alone")
+	}
+	if c.expirations.Len() != 0 {
+		t.Error("Expected the expiration heap to be empty after the expired entry was popped")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	c := New[string, []byte](NoExpiration, 0)
+	c.Set("test", []byte("value"), NoExpiration)
+
+	c.Delete("test")
+
+	if _, ok := c.Read("test"); ok {
+		t.Error("Expected the deleted key to be gone")
+	}
+
+	// Deleting a key that was never present should be a no-op.
+	c.Delete("nonexistent")
+}
+
+func TestOnEvicted(t *testing.T) {
+	c := New[string, []byte](NoExpiration, 0)
+
+	var gotKey string
+	var gotReason EvictionReason
+	c.SetOnEvicted(func(key string, value []byte, reason EvictionReason) {
+		gotKey = key
+		gotReason = reason
+	})
+
+	c.Set("test", []byte("value"), NoExpiration)
+	c.Delete("test")
 
-	nonexistent := cache.Get("lol")
-	if nonexistent != nil {
-		t.Error("Non-existent values should be nil")
+	if gotKey != "test" || gotReason != ReasonManualDelete {
+		t.Errorf("Expected OnEvicted to fire with key %q and ReasonManualDelete, got key %q reason %v", "test", gotKey, gotReason)
+	}
+
+	c.Set("test", []byte("first"), NoExpiration)
+	c.Set("test", []byte("second"), NoExpiration)
+	if gotKey != "test" || gotReason != ReasonReplaced {
+		t.Errorf("Expected overwriting a key to fire OnEvicted with ReasonReplaced, got key %q reason %v", gotKey, gotReason)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	c := New[string, []byte](NoExpiration, 0)
+
+	if err := c.Add("test", []byte("first"), NoExpiration); err != nil {
+		t.Errorf("Expected no error adding a new key, got %v", err)
+	}
+
+	if err := c.Add("test", []byte("second"), NoExpiration); err == nil {
+		t.Error("Expected an error adding a key that already exists")
+	}
+
+	readVal, _ := c.Read("test")
+	if !reflect.DeepEqual(readVal, []byte("first")) {
+		t.Error("Add should not have overwritten the existing value")
+	}
+}
+
+func TestReplace(t *testing.T) {
+	c := New[string, []byte](NoExpiration, 0)
+
+	if err := c.Replace("test", []byte("value"), NoExpiration); err == nil {
+		t.Error("Expected an error replacing a key that does not exist")
+	}
+
+	c.Set("test", []byte("original"), NoExpiration)
+	if err := c.Replace("test", []byte("replaced"), NoExpiration); err != nil {
+		t.Errorf("Expected no error replacing an existing key, got %v", err)
+	}
+
+	readVal, _ := c.Read("test")
+	if !reflect.DeepEqual(readVal, []byte("replaced")) {
+		t.Error("Replace should have overwritten the existing value")
 	}
 }
 
 func TestEviction(t *testing.T) {
-	cache := NewCache()
+	c := New[string, []byte](NoExpiration, 0)
 
 	testKey := "test"
 	testVal := []byte("This is a test!")
-	expiration := time.Now().Add(time.Hour)
 
-	cache.Set(testKey, testVal, expiration)
+	c.Set(testKey, testVal, time.Hour)
 
 	// This is pretty jank, fix this testing by not relying on time
-	cache.StartEviction(0, time.Millisecond)
+	c.StartEviction(0, time.Millisecond)
 	time.Sleep(5 * time.Millisecond)
-	cache.StopEviction()
+	c.StopEviction()
 
-	shouldBeEvicted := cache.Get(testKey)
-	if shouldBeEvicted != nil {
+	if _, ok := c.Read(testKey); ok {
 		t.Error("Expected the value to be evicted")
 	}
 }
 
 func TestStartEvictionTwice(t *testing.T) {
-	cache := NewCache()
-	err := cache.StartEviction(5000, time.Second)
+	c := New[string, []byte](NoExpiration, 0)
+
+	err := c.StartEviction(5000, time.Second)
 	if err != nil {
 		t.Error("Expected no error when starting eviction for the first time")
 	}
 
-	err = cache.StartEviction(5000, time.Second)
+	err = c.StartEviction(5000, time.Second)
 	if err == nil {
 		t.Error("Expected error when starting eviction twice")
 	}
 }
 
 func TestExpiration(t *testing.T) {
-	cache := NewCache()
+	c := New[string, []byte](NoExpiration, 0)
 
 	testKey := "test"
 	testVal := []byte("This is a test!")
 	expiration := time.Now().Add(time.Hour)
 
-	cache.Set(testKey, testVal, expiration)
+	c.Set(testKey, testVal, time.Hour)
 
-	cache.clock = &clock{instant: expiration.Add(1 * time.Second)}
+	c.clock = &clock{instant: expiration.Add(1 * time.Second)}
 
-	shouldBeEvicted := cache.Get(testKey)
-
-	if shouldBeEvicted != nil {
+	if _, ok := c.Read(testKey); ok {
 		t.Error("Expected the expired item to be evicted")
 	}
 }
 
 func TestBytesReferenced(t *testing.T) {
-	cache := NewCache()
+	c := New[string, []byte](NoExpiration, 0)
 
 	testKey := "test"
 	testVal := []byte("This is a test!")
-	expiration := time.Now().Add(time.Hour)
 
-	cache.Set(testKey, testVal, expiration)
+	c.Set(testKey, testVal, time.Hour)
 
-	size := cache.BytesReferenced()
+	size := c.BytesReferenced()
 	if size != uint64(len(testVal)) {
 		t.Error("Expected size of cache to be equal to sum of items")
 	}
 }
 
 func BenchmarkConcurrentInserts(b *testing.B) {
-	cache := NewCache()
-	expiration := time.Now().Add(time.Hour)
+	c := New[string, []byte](NoExpiration, 0)
 	var strings []string
 	for i := 0; i < 100000; i++ {
 		strings = append(strings, strconv.Itoa(i))
@@ -118,7 +252,7 @@ func BenchmarkConcurrentInserts(b *testing.B) {
 		counter := 0
 		for pb.Next() {
 			s := strings[counter%len(strings)]
-			cache.Set(s, []byte(s), expiration)
+			c.Set(s, []byte(s), time.Hour)
 			counter++
 		}
 	})
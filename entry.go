@@ -5,18 +5,20 @@ import (
 	"time"
 )
 
-// An Entry represents a value in a cache
-type entry struct {
-	data        []byte
+// entry is the value wrapper stored inside a Cache, tracking its expiration,
+// backing LRU list element, and position in the expiration heap. heapIndex
+// is -1 when the entry is not tracked in the heap, which is the case for
+// entries with noExpire set.
+type entry[V any] struct {
+	value       V
 	expiration  time.Time
+	noExpire    bool
 	listElement *list.Element
+	heapIndex   int
 }
 
-func (e *entry) update(value []byte, expiration time.Time) {
-	e.data = value
-	e.expiration = expiration
-}
-
-func (e *entry) read() []byte {
-	return e.data
+// expired reports whether the entry's TTL has elapsed as of now. Entries
+// with noExpire set never expire.
+func (e *entry[V]) expired(now time.Time) bool {
+	return !e.noExpire && e.expiration.Before(now)
 }
@@ -0,0 +1,64 @@
+package cache
+
+// EvictionReason describes why an entry left the cache, passed to any
+// callback registered via Cache.SetOnEvicted.
+type EvictionReason int
+
+const (
+	// ReasonExpired means the entry's TTL elapsed before it was evicted.
+	ReasonExpired EvictionReason = iota
+	// ReasonMemoryPressure means the entry was evicted from the LRU tail to
+	// bring the cache back under its configured memory limit.
+	ReasonMemoryPressure
+	// ReasonManualDelete means the entry was removed by an explicit call to
+	// Cache.Delete.
+	ReasonManualDelete
+	// ReasonReplaced means the entry's value was overwritten by a later
+	// Set, SetDefault, Add, or Replace call, or the entry was discarded by
+	// Load, which clears every existing entry before restoring a snapshot.
+	ReasonReplaced
+)
+
+// evictionRecord is a buffered (key, value, reason) tuple waiting to be
+// delivered to OnEvicted once the cache's lock has been released.
+type evictionRecord[K comparable, V any] struct {
+	key    K
+	value  V
+	reason EvictionReason
+}
+
+// SetOnEvicted registers a callback invoked after every eviction, once the
+// cache's internal lock has been released. This makes it safe for the
+// callback to call back into the cache (e.g. Read or Set) without
+// deadlocking against the eviction that triggered it.
+func (c *lruCore[K, V]) SetOnEvicted(f func(key K, value V, reason EvictionReason)) {
+	c.Lock()
+	defer c.Unlock()
+	c.onEvicted = f
+}
+
+// Not thread safe. Appends a (key, value, reason) record to be delivered to
+// OnEvicted once the caller releases the lock and calls flushEvictions.
+func (c *lruCore[K, V]) bufferEviction(key K, value V, reason EvictionReason) {
+	if c.onEvicted == nil {
+		return
+	}
+	c.evictionBuffer = append(c.evictionBuffer, evictionRecord[K, V]{key: key, value: value, reason: reason})
+}
+
+// flushEvictions invokes OnEvicted for every record buffered since the last
+// flush. Must be called without holding c's lock.
+func (c *lruCore[K, V]) flushEvictions() {
+	c.Lock()
+	buffered := c.evictionBuffer
+	c.evictionBuffer = nil
+	cb := c.onEvicted
+	c.Unlock()
+
+	if cb == nil {
+		return
+	}
+	for _, rec := range buffered {
+		cb(rec.key, rec.value, rec.reason)
+	}
+}
@@ -0,0 +1,35 @@
+package cache
+
+// expirationQueue is a container/heap min-heap of entries ordered by
+// expiration time. It lets the janitor find and evict expired entries in
+// O(log n) per eviction instead of scanning the whole map. Entries with
+// noExpire set are never pushed onto the queue.
+type expirationQueue[V any] []*entry[V]
+
+func (q expirationQueue[V]) Len() int { return len(q) }
+
+func (q expirationQueue[V]) Less(i, j int) bool {
+	return q[i].expiration.Before(q[j].expiration)
+}
+
+func (q expirationQueue[V]) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].heapIndex = i
+	q[j].heapIndex = j
+}
+
+func (q *expirationQueue[V]) Push(x any) {
+	e := x.(*entry[V])
+	e.heapIndex = len(*q)
+	*q = append(*q, e)
+}
+
+func (q *expirationQueue[V]) Pop() any {
+	old := *q
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	e.heapIndex = -1
+	*q = old[:n-1]
+	return e
+}
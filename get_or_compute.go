@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// inflightCall represents a GetOrCompute computation in progress for a
+// single key. Concurrent callers for that key wait on wg instead of each
+// calling fn themselves.
+type inflightCall[V any] struct {
+	wg    sync.WaitGroup
+	value V
+	err   error
+}
+
+// GetOrCompute returns the cached value for key, or calls fn to compute it
+// if key is missing or expired, caching the result with the returned TTL.
+// Concurrent callers for the same missing key block on a single in-flight
+// call to fn rather than racing to call it themselves, protecting whatever
+// backend fn hits from a cache-stampede.
+func (c *lruCore[K, V]) GetOrCompute(key K, fn func() (V, time.Duration, error)) (V, error) {
+	if value, ok := c.Read(key); ok {
+		return value, nil
+	}
+
+	c.Lock()
+	// A call for key may have finished between the Read above and taking
+	// the lock; use its cached result rather than calling fn again.
+	if e, ok := c.data[key]; ok && !e.expired(c.clock.now()) {
+		c.lru.MoveToFront(e.listElement)
+		value := e.value
+		c.Unlock()
+		return value, nil
+	}
+	if call, ok := c.inflight[key]; ok {
+		c.Unlock()
+		call.wg.Wait()
+		return call.value, call.err
+	}
+
+	call := &inflightCall[V]{}
+	call.wg.Add(1)
+	if c.inflight == nil {
+		c.inflight = map[K]*inflightCall[V]{}
+	}
+	c.inflight[key] = call
+	c.Unlock()
+
+	value, ttl, err := fn()
+	call.value, call.err = value, err
+	if err == nil {
+		c.Set(key, value, ttl)
+	}
+
+	// Keep the inflight entry around until after the value lands in the
+	// cache, so callers that arrive while Set is running still wait on
+	// call.wg instead of racing fn a second time.
+	c.Lock()
+	delete(c.inflight, key)
+	c.Unlock()
+
+	call.wg.Done()
+
+	return value, err
+}
@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetOrComputeCachesResult(t *testing.T) {
+	c := New[string, []byte](NoExpiration, 0)
+
+	var calls int32
+	fn := func() ([]byte, time.Duration, error) {
+		atomic.AddInt32(&calls, 1)
+		return []byte("computed"), time.Hour, nil
+	}
+
+	value, err := c.GetOrCompute("test", fn)
+	if err != nil {
+		t.Fatalf("GetOrCompute returned an error: %v", err)
+	}
+	if !reflect.DeepEqual(value, []byte("computed")) {
+		t.Errorf("Expected the computed value, got %q", value)
+	}
+
+	value, err = c.GetOrCompute("test", fn)
+	if err != nil {
+		t.Fatalf("GetOrCompute returned an error: %v", err)
+	}
+	if !reflect.DeepEqual(value, []byte("computed")) {
+		t.Errorf("Expected the cached value on the second call, got %q", value)
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected fn to be called once across both GetOrCompute calls, got %d", got)
+	}
+}
+
+func TestGetOrComputeSingleFlight(t *testing.T) {
+	c := New[string, []byte](NoExpiration, 0)
+
+	var calls int32
+	release := make(chan struct{})
+	fn := func() ([]byte, time.Duration, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return []byte("computed"), time.Hour, nil
+	}
+
+	const concurrent = 10
+	var wg sync.WaitGroup
+	wg.Add(concurrent)
+	for i := 0; i < concurrent; i++ {
+		go func() {
+			defer wg.Done()
+			value, err := c.GetOrCompute("test", fn)
+			if err != nil {
+				t.Errorf("GetOrCompute returned an error: %v", err)
+			}
+			if !reflect.DeepEqual(value, []byte("computed")) {
+				t.Errorf("Expected every caller to see the computed value, got %q", value)
+			}
+		}()
+	}
+
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected fn to be called exactly once for %d concurrent callers, got %d", concurrent, got)
+	}
+}
+
+func TestGetOrComputeError(t *testing.T) {
+	c := New[string, []byte](NoExpiration, 0)
+
+	wantErr := errors.New("backend unavailable")
+	_, err := c.GetOrCompute("test", func() ([]byte, time.Duration, error) {
+		return nil, 0, wantErr
+	})
+	if err != wantErr {
+		t.Errorf("Expected GetOrCompute to propagate fn's error, got %v", err)
+	}
+
+	if _, ok := c.Read("test"); ok {
+		t.Error("Expected a failed computation not to populate the cache")
+	}
+}
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"hash/fnv"
+	"runtime"
+	"time"
+)
+
+// ShardedCache wraps N independent Cache shards, routing each key to a
+// shard by its fnv64 hash. This spreads the single global mutex that Cache
+// takes on every Read/Set across N locks, eliminating the contention
+// bottleneck that BenchmarkConcurrentInserts shows for a single Cache under
+// concurrent writers. ShardedCache exposes Cache's Set, SetDefault, Add,
+// Replace, Read, Delete, DeleteExpired, SetOnEvicted, BytesReferenced,
+// StartEviction and StopEviction. It has no GetOrCompute, Save, SaveFile,
+// Load or LoadFile, and its keys must have string as their underlying type.
+type ShardedCache[K ~string, V any] struct {
+	shards []*Cache[K, V]
+}
+
+// NewSharded constructs a ShardedCache of shardCount shards, each built via
+// New with the given defaultExpiration and cleanupInterval. If shardCount
+// is 0, runtime.GOMAXPROCS(0) is used.
+func NewSharded[K ~string, V any](shardCount int, defaultExpiration, cleanupInterval time.Duration) *ShardedCache[K, V] {
+	if shardCount == 0 {
+		shardCount = runtime.GOMAXPROCS(0)
+	}
+
+	sc := &ShardedCache[K, V]{shards: make([]*Cache[K, V], shardCount)}
+	for i := range sc.shards {
+		sc.shards[i] = New[K, V](defaultExpiration, cleanupInterval)
+	}
+	return sc
+}
+
+// Not thread safe to call concurrently with resharding, but ShardedCache
+// never reshards after construction.
+func (sc *ShardedCache[K, V]) shardFor(key K) *Cache[K, V] {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return sc.shards[h.Sum64()%uint64(len(sc.shards))]
+}
+
+// Set routes to Cache.Set on key's shard.
+func (sc *ShardedCache[K, V]) Set(key K, value V, ttl time.Duration) {
+	sc.shardFor(key).Set(key, value, ttl)
+}
+
+// SetDefault routes to Cache.SetDefault on key's shard.
+func (sc *ShardedCache[K, V]) SetDefault(key K, value V) {
+	sc.shardFor(key).SetDefault(key, value)
+}
+
+// Add routes to Cache.Add on key's shard.
+func (sc *ShardedCache[K, V]) Add(key K, value V, ttl time.Duration) error {
+	return sc.shardFor(key).Add(key, value, ttl)
+}
+
+// Replace routes to Cache.Replace on key's shard.
+func (sc *ShardedCache[K, V]) Replace(key K, value V, ttl time.Duration) error {
+	return sc.shardFor(key).Replace(key, value, ttl)
+}
+
+// Read routes to Cache.Read on key's shard.
+func (sc *ShardedCache[K, V]) Read(key K) (V, bool) {
+	return sc.shardFor(key).Read(key)
+}
+
+// Delete routes to Cache.Delete on key's shard.
+func (sc *ShardedCache[K, V]) Delete(key K) {
+	sc.shardFor(key).Delete(key)
+}
+
+// DeleteExpired runs Cache.DeleteExpired on every shard.
+func (sc *ShardedCache[K, V]) DeleteExpired() {
+	for _, shard := range sc.shards {
+		shard.DeleteExpired()
+	}
+}
+
+// SetOnEvicted registers f as the OnEvicted callback on every shard.
+func (sc *ShardedCache[K, V]) SetOnEvicted(f func(key K, value V, reason EvictionReason)) {
+	for _, shard := range sc.shards {
+		shard.SetOnEvicted(f)
+	}
+}
+
+// BytesReferenced returns the sum of BytesReferenced across all shards.
+func (sc *ShardedCache[K, V]) BytesReferenced() uint64 {
+	var total uint64
+	for _, shard := range sc.shards {
+		total += shard.BytesReferenced()
+	}
+	return total
+}
+
+// StartEviction starts one background janitor per shard, each capped at
+// memoryLimit/len(shards) so the cache's aggregate memory stays near
+// memoryLimit overall.
+func (sc *ShardedCache[K, V]) StartEviction(memoryLimit uint64, checkInterval time.Duration) error {
+	perShard := memoryLimit / uint64(len(sc.shards))
+	for _, shard := range sc.shards {
+		if err := shard.StartEviction(perShard, checkInterval); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StopEviction stops the background janitor on every shard.
+func (sc *ShardedCache[K, V]) StopEviction() {
+	for _, shard := range sc.shards {
+		shard.StopEviction()
+	}
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestShardedCacheSetRead(t *testing.T) {
+	sc := NewSharded[string, []byte](4, NoExpiration, 0)
+
+	testKey := "test"
+	testVal := []byte("This is a test!")
+
+	sc.Set(testKey, testVal, time.Hour)
+
+	readVal, ok := sc.Read(testKey)
+	if !ok || !reflect.DeepEqual(readVal, testVal) {
+		t.Error("Key put in sharded store not equal to key read from sharded store")
+	}
+}
+
+func TestShardedCacheBytesReferenced(t *testing.T) {
+	sc := NewSharded[string, []byte](4, NoExpiration, 0)
+
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i)
+		sc.Set(key, []byte(key), time.Hour)
+	}
+
+	var want uint64
+	for i := 0; i < 100; i++ {
+		want += uint64(len(strconv.Itoa(i)))
+	}
+
+	if got := sc.BytesReferenced(); got != want {
+		t.Errorf("Expected aggregate BytesReferenced across shards to be %d, got %d", want, got)
+	}
+}
+
+func BenchmarkConcurrentInsertsSingleVsSharded(b *testing.B) {
+	var strs []string
+	for i := 0; i < 100000; i++ {
+		strs = append(strs, strconv.Itoa(i))
+	}
+
+	b.Run("Single", func(b *testing.B) {
+		c := New[string, []byte](NoExpiration, 0)
+
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			counter := 0
+			for pb.Next() {
+				s := strs[counter%len(strs)]
+				c.Set(s, []byte(s), time.Hour)
+				counter++
+			}
+		})
+	})
+
+	b.Run("Sharded", func(b *testing.B) {
+		sc := NewSharded[string, []byte](0, NoExpiration, 0)
+
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			counter := 0
+			for pb.Next() {
+				s := strs[counter%len(strs)]
+				sc.Set(s, []byte(s), time.Hour)
+				counter++
+			}
+		})
+	})
+}
@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"encoding/gob"
+	"io"
+	"os"
+	"time"
+)
+
+// snapshotItem is the gob-encodable representation of a single cache entry,
+// used by Save/Load. entry itself isn't gob-encodable because it embeds a
+// *list.Element and unexported fields.
+type snapshotItem[K comparable, V any] struct {
+	Key        K
+	Value      V
+	Expiration time.Time
+	NoExpire   bool
+}
+
+// Save writes a gob-encoded snapshot of every currently unexpired entry to
+// w, skipping already-expired items. Entries are written from least- to
+// most-recently-used, so Load can rebuild the LRU order by inserting them
+// in the order it reads them back.
+func (c *lruCore[K, V]) Save(w io.Writer) error {
+	c.Lock()
+	now := c.clock.now()
+	items := make([]snapshotItem[K, V], 0, len(c.data))
+	for e := c.lru.Back(); e != nil; e = e.Prev() {
+		key := e.Value.(K)
+		entry := c.data[key]
+		if entry.expired(now) {
+			continue
+		}
+		items = append(items, snapshotItem[K, V]{
+			Key:        key,
+			Value:      entry.value,
+			Expiration: entry.expiration,
+			NoExpire:   entry.noExpire,
+		})
+	}
+	c.Unlock()
+
+	return gob.NewEncoder(w).Encode(items)
+}
+
+// SaveFile is equivalent to Save, writing the snapshot to the file at path.
+func (c *lruCore[K, V]) SaveFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.Save(f)
+}
+
+// Load replaces the cache's contents with the gob-encoded snapshot read
+// from r, dropping any items whose expiration has already passed. Entries
+// are restored in their original LRU order.
+func (c *lruCore[K, V]) Load(r io.Reader) error {
+	var items []snapshotItem[K, V]
+	if err := gob.NewDecoder(r).Decode(&items); err != nil {
+		return err
+	}
+
+	c.Lock()
+	for key, e := range c.data {
+		c.removeEntryLocked(key, e, ReasonReplaced)
+	}
+
+	now := c.clock.now()
+	for _, item := range items {
+		if !item.NoExpire && item.Expiration.Before(now) {
+			continue
+		}
+		c.setLocked(item.Key, item.Value, item.Expiration, item.NoExpire)
+	}
+	c.Unlock()
+	c.flushEvictions()
+	return nil
+}
+
+// LoadFile is equivalent to Load, reading the snapshot from the file at
+// path.
+func (c *lruCore[K, V]) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.Load(f)
+}
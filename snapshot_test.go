@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestSaveLoad(t *testing.T) {
+	c := New[string, []byte](NoExpiration, 0)
+	c.Set("a", []byte("alpha"), time.Hour)
+	c.Set("b", []byte("beta"), NoExpiration)
+
+	var buf bytes.Buffer
+	if err := c.Save(&buf); err != nil {
+		t.Fatalf("Save returned an error: %v", err)
+	}
+
+	// Mutate the original cache after saving to prove Load restores the
+	// saved snapshot rather than reflecting the original cache's live state.
+	c.Set("a", []byte("mutated"), time.Hour)
+	c.Delete("b")
+
+	fresh := New[string, []byte](NoExpiration, 0)
+	if err := fresh.Load(&buf); err != nil {
+		t.Fatalf("Load returned an error: %v", err)
+	}
+
+	readA, ok := fresh.Read("a")
+	if !ok || !reflect.DeepEqual(readA, []byte("alpha")) {
+		t.Errorf("Expected Load to restore the pre-save value for \"a\", got %q ok=%v", readA, ok)
+	}
+
+	readB, ok := fresh.Read("b")
+	if !ok || !reflect.DeepEqual(readB, []byte("beta")) {
+		t.Errorf("Expected Load to restore the pre-save value for \"b\", got %q ok=%v", readB, ok)
+	}
+
+	if got, want := fresh.BytesReferenced(), uint64(len("alpha")+len("beta")); got != want {
+		t.Errorf("Expected BytesReferenced of the loaded cache to be %d, got %d", want, got)
+	}
+}
+
+func TestSaveSkipsExpired(t *testing.T) {
+	c := New[string, []byte](NoExpiration, 0)
+	c.Set("expired", []byte("a"), time.Hour)
+	c.clock = &clock{instant: time.Now().Add(2 * time.Hour)}
+
+	var buf bytes.Buffer
+	if err := c.Save(&buf); err != nil {
+		t.Fatalf("Save returned an error: %v", err)
+	}
+
+	fresh := New[string, []byte](NoExpiration, 0)
+	if err := fresh.Load(&buf); err != nil {
+		t.Fatalf("Load returned an error: %v", err)
+	}
+
+	if _, ok := fresh.Read("expired"); ok {
+		t.Error("Expected Save to have skipped the already-expired entry")
+	}
+}
+
+func TestLoadReplacesExistingContents(t *testing.T) {
+	c := New[string, []byte](NoExpiration, 0)
+	c.Set("a", []byte("alpha"), NoExpiration)
+
+	var buf bytes.Buffer
+	if err := c.Save(&buf); err != nil {
+		t.Fatalf("Save returned an error: %v", err)
+	}
+
+	var evicted []string
+	target := New[string, []byte](NoExpiration, 0)
+	target.Set("a", []byte("stale"), NoExpiration)
+	target.Set("unrelated", []byte("leftover"), NoExpiration)
+	target.SetOnEvicted(func(key string, value []byte, reason EvictionReason) {
+		evicted = append(evicted, key)
+		if reason != ReasonReplaced {
+			t.Errorf("Expected %q to be evicted with ReasonReplaced, got %v", key, reason)
+		}
+	})
+
+	if err := target.Load(&buf); err != nil {
+		t.Fatalf("Load returned an error: %v", err)
+	}
+
+	if _, ok := target.Read("unrelated"); ok {
+		t.Error("Expected Load to replace the cache's contents, but \"unrelated\" survived")
+	}
+
+	readA, ok := target.Read("a")
+	if !ok || !reflect.DeepEqual(readA, []byte("alpha")) {
+		t.Errorf("Expected Load to overwrite \"a\" with the snapshot value, got %q ok=%v", readA, ok)
+	}
+
+	if got, want := target.BytesReferenced(), uint64(len("alpha")); got != want {
+		t.Errorf("Expected BytesReferenced to reflect only the loaded snapshot, got %d, want %d", got, want)
+	}
+
+	// Load should have flushed the eviction records for every entry it wiped
+	// out before replaying the snapshot, not just the one the snapshot
+	// happens to share a key with.
+	sort.Strings(evicted)
+	if !reflect.DeepEqual(evicted, []string{"a", "unrelated"}) {
+		t.Errorf("Expected OnEvicted to fire for both prior entries by the time Load returns, got %v", evicted)
+	}
+}
+
+func TestSaveFileLoadFile(t *testing.T) {
+	c := New[string, []byte](NoExpiration, 0)
+	c.Set("test", []byte("value"), NoExpiration)
+
+	path := filepath.Join(t.TempDir(), "cache.gob")
+	if err := c.SaveFile(path); err != nil {
+		t.Fatalf("SaveFile returned an error: %v", err)
+	}
+
+	fresh := New[string, []byte](NoExpiration, 0)
+	if err := fresh.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile returned an error: %v", err)
+	}
+
+	readVal, ok := fresh.Read("test")
+	if !ok || !reflect.DeepEqual(readVal, []byte("value")) {
+		t.Error("Expected LoadFile to restore the saved value")
+	}
+}